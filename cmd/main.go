@@ -18,32 +18,44 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
-	"net"
+	"net/http"
 	"os"
-	"syscall"
-
-	"github.com/vishvananda/netlink"
-	"k8s.io/klog/v2"
-	"sigs.k8s.io/controller-runtime/pkg/event"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	batchv1 "tutorial.kubebuilder.io/project/api/v1"
+	"tutorial.kubebuilder.io/project/ebpf"
 	"tutorial.kubebuilder.io/project/internal/controller"
+	"tutorial.kubebuilder.io/project/monitoring"
 	//+kubebuilder:scaffold:imports
 )
 
+// eventSource selects which backend(s) feed the typed netlink/flow change
+// stream; see the --event-source flag.
+type eventSource string
+
+const (
+	eventSourceNetlink eventSource = "netlink"
+	eventSourceEBPF    eventSource = "ebpf"
+	eventSourceBoth    eventSource = "both"
+)
+
 // +kubebuilder:docs-gen:collapse=Imports
 
 /*
@@ -64,6 +76,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(batchv1.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -73,79 +86,23 @@ CronJob controller's `SetupWithManager` method.
 */
 
 func main() {
-	// Create channels to receive notifications for link, address, and route changes
-	chLink := make(chan netlink.LinkUpdate)
-	doneLink := make(chan struct{})
-	defer close(doneLink)
-
-	chAddr := make(chan netlink.AddrUpdate)
-	doneAddr := make(chan struct{})
-	defer close(doneAddr)
-
-	chRoute := make(chan netlink.RouteUpdate)
-	doneRoute := make(chan struct{})
-	defer close(doneRoute)
-
-	c := make(chan event.GenericEvent)
-
-	// Subscribe to the address updates
-	if err := netlink.AddrSubscribe(chAddr, doneAddr); err != nil {
-		fmt.Println("Error:", err)
-		return
-	}
-
-	// Subscribe to the link updates
-	if err := netlink.LinkSubscribe(chLink, doneLink); err != nil {
-		fmt.Println("Error:", err)
-		return
-	}
-
-	// Subscribe to the route updates
-	if err := netlink.RouteSubscribe(chRoute, doneRoute); err != nil {
-		fmt.Println("Error:", err)
-		return
-	}
-
-	// Create maps to keep track of interfaces and newly created interfaces
-	newlyCreated := make(map[string]bool)
-	interfaces := make(map[string]bool)
-
-	// Get the list of existing links and add them to the interfaces map
-	links, err := netlink.LinkList()
-	if err != nil {
-		fmt.Println("Error:", err)
-		return
-	}
-	for _, link := range links {
-		interfaces[link.Attrs().Name] = true
-	}
-
-	fmt.Println("Monitoring started. Press Ctrl+C to stop it.")
-
-	// Start an infinite loop to handle the notifications
-	/************************* GO ROUTINE *******************************/
-	// TOASK: is it ok?
-	go func() {
-		for {
-			select {
-			case updateLink := <-chLink:
-				handleLinkUpdate(updateLink, interfaces, newlyCreated, c)
-			case updateAddr := <-chAddr:
-				handleAddrUpdate(updateAddr, interfaces, c)
-			case updateRoute := <-chRoute:
-				handleRouteUpdate(updateRoute, c)
-			}
-		}
-	}()
-
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var eventSourceFlag string
+	var requiredCRDsFlag string
+	var netlinkDebounce time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&eventSourceFlag, "event-source", string(eventSourceNetlink),
+		"Backend(s) feeding the interface/flow change stream: netlink, ebpf, or both.")
+	flag.StringVar(&requiredCRDsFlag, "required-crds", "cronjobs.batch.tutorial.kubebuilder.io",
+		"Comma-separated CRD names that must be Established before the downstream controllers are registered.")
+	flag.DurationVar(&netlinkDebounce, "netlink-debounce", 250*time.Millisecond,
+		"Window over which to coalesce netlink updates for the same interface before reconciling. Zero disables debouncing.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -168,68 +125,92 @@ func main() {
 
 	// +kubebuilder:docs-gen:collapse=old stuff
 
-	if err = (&controller.CronJobReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr, c); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "CronJob")
+	es := eventSource(eventSourceFlag)
+	switch es {
+	case eventSourceNetlink, eventSourceEBPF, eventSourceBoth:
+	default:
+		setupLog.Error(fmt.Errorf("invalid value %q", eventSourceFlag), "unrecognized --event-source")
 		os.Exit(1)
 	}
-}
 
-func handleLinkUpdate(updateLink netlink.LinkUpdate, interfaces map[string]bool, newlyCreated map[string]bool, c chan<- event.GenericEvent) {
-	if updateLink.Header.Type == syscall.RTM_DELLINK {
-		// Link has been removed
-		fmt.Println("Interface removed:", updateLink.Link.Attrs().Name)
-		delete(interfaces, updateLink.Link.Attrs().Name)
-		delete(newlyCreated, updateLink.Link.Attrs().Name)
-	} else if !interfaces[updateLink.Link.Attrs().Name] && updateLink.Header.Type == syscall.RTM_NEWLINK {
-		// New link has been added
-		fmt.Println("Interface added")
-		interfaces[updateLink.Link.Attrs().Name] = true
-		newlyCreated[updateLink.Link.Attrs().Name] = true
-	} else if updateLink.Header.Type == syscall.RTM_NEWLINK {
-		// Link has been modified
-		if updateLink.Link.Attrs().Flags&net.FlagUp != 0 {
-			fmt.Println("Interface", updateLink.Link.Attrs().Name, "is up")
-			delete(newlyCreated, updateLink.Link.Attrs().Name)
-		} else if !newlyCreated[updateLink.Link.Attrs().Name] {
-			fmt.Println("Interface", updateLink.Link.Attrs().Name, "is down")
+	netlinkSource := monitoring.NewNetlinkSource(monitoring.WatchLink | monitoring.WatchAddr | monitoring.WatchRoute)
+	if netlinkDebounce > 0 {
+		netlinkSource.Debounce = monitoring.NewDebouncer(netlinkDebounce, 50)
+	}
+
+	var sources []source.Source
+	netlinkSourceStarted := false
+	if es == eventSourceNetlink || es == eventSourceBoth {
+		sources = append(sources, netlinkSource)
+		netlinkSourceStarted = true
+	}
+	if es == eventSourceEBPF || es == eventSourceBoth {
+		ebpfSource, err := ebpf.NewSource()
+		if err != nil {
+			setupLog.Error(err, "unable to load ebpf event source")
+			if es == eventSourceEBPF {
+				setupLog.Info("falling back to netlink-only monitoring")
+				sources = append(sources, netlinkSource)
+				netlinkSourceStarted = true
+			}
+		} else {
+			if err := mgr.AddReadyzCheck("ebpf", ebpfSource.Healthy); err != nil {
+				setupLog.Error(err, "unable to register ebpf health check")
+				os.Exit(1)
+			}
+			sources = append(sources, ebpfSource)
 		}
 	}
-	send(c)
-}
 
-func handleAddrUpdate(updateAddr netlink.AddrUpdate, interfaces map[string]bool, c chan<- event.GenericEvent) {
-	iface, err := net.InterfaceByIndex(updateAddr.LinkIndex)
-	if err != nil {
-		fmt.Println("Address (", updateAddr.LinkAddress.IP, ") removed from the deleted interface")
-		return
+	// NetnsWatcher dispatches through netlinkSource, which only becomes ready
+	// once the manager drives its Start method - which only happens if it was
+	// registered above via WatchesRawSource. Wiring NetnsWatcher up
+	// unconditionally would otherwise wedge every per-pod event forever
+	// whenever --event-source=ebpf succeeds in loading.
+	if netlinkSourceStarted {
+		if err = monitoring.NewNetnsWatcher(mgr.GetClient(), os.Getenv("NODE_NAME"), netlinkSource).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "NetnsWatcher")
+			os.Exit(1)
+		}
 	}
-	if updateAddr.NewAddr {
-		// New address has been added
-		fmt.Println("New address (", updateAddr.LinkAddress.IP, ") added to the interface:", iface.Name)
-	} else {
-		// Address has been removed
-		fmt.Println("Address (", updateAddr.LinkAddress.IP, ") removed from the interface:", iface.Name)
+
+	setupCronJobController := func(ctx context.Context) error {
+		return (&controller.CronJobReconciler{
+			Client: mgr.GetClient(),
+			Scheme: mgr.GetScheme(),
+		}).SetupWithManager(mgr, sources...)
 	}
-	send(c)
-}
 
-func handleRouteUpdate(updateRoute netlink.RouteUpdate, c chan<- event.GenericEvent) {
-	if updateRoute.Type == syscall.RTM_NEWROUTE {
-		// New route has been added
-		fmt.Println("New route added:", updateRoute.Route.Dst)
-	} else if updateRoute.Type == syscall.RTM_DELROUTE {
-		// Route has been removed
-		fmt.Println("Route removed:", updateRoute.Route.Dst)
+	var requiredCRDs []string
+	for _, name := range strings.Split(requiredCRDsFlag, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			requiredCRDs = append(requiredCRDs, name)
+		}
 	}
-	send(c)
-}
 
-// send a channel with generic event type
-func send(c chan<- event.GenericEvent) {
-	ge := event.GenericEvent{}
-	c <- ge
-	klog.Infof("Starting Netlink routine")
+	if len(requiredCRDs) == 0 {
+		if err := setupCronJobController(context.Background()); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "CronJob")
+			os.Exit(1)
+		}
+	} else {
+		waitForCRDs := &controller.WaitForCRDsReconciler{
+			Client:       mgr.GetClient(),
+			RequiredCRDs: requiredCRDs,
+			OnReady:      setupCronJobController,
+		}
+		if err = waitForCRDs.SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "WaitForCRDs")
+			os.Exit(1)
+		}
+		if err = mgr.AddHealthzCheck("required-crds", func(_ *http.Request) error {
+			if !waitForCRDs.Ready() {
+				return fmt.Errorf("required CRDs not yet established: %s", strings.Join(requiredCRDs, ", "))
+			}
+			return nil
+		}); err != nil {
+			setupLog.Error(err, "unable to register required-crds health check")
+			os.Exit(1)
+		}
+	}
 }
@@ -0,0 +1,15 @@
+package ebpf
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var ringbufDrops = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "ebpf_source_ringbuf_drops_total",
+	Help: "Number of errors returned while reading the BPF ring buffer. Events the probes discard in the kernel because the ring buffer is full are not counted.",
+})
+
+func init() {
+	metrics.Registry.MustRegister(ringbufDrops)
+}
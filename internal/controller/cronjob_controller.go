@@ -0,0 +1,83 @@
+/*
+Copyright 2023 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	batchv1 "tutorial.kubebuilder.io/project/api/v1"
+)
+
+// CronJobReconciler reconciles a CronJob object.
+type CronJobReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=batch.tutorial.kubebuilder.io,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch.tutorial.kubebuilder.io,resources=cronjobs/status,verbs=get;update;patch
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+func (r *CronJobReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	// Interface/flow changes don't carry enough information on their own to
+	// decide anything; mapChangeToCronJobs enqueues every CronJob whenever one
+	// comes through. Reconcile does not read or change the CronJob named in
+	// req: it returns an empty Result for every request, whether it came from
+	// a CronJob event or from an interface/flow change.
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager. Each source in
+// sources (e.g. a monitoring.NetlinkSource or an ebpf.Source) is registered
+// with WatchesRawSource without predicates, so every change a source delivers
+// is turned into reconcile requests; no filtering by interface, address
+// family or event kind happens here.
+func (r *CronJobReconciler) SetupWithManager(mgr ctrl.Manager, sources ...source.Source) error {
+	bldr := ctrl.NewControllerManagedBy(mgr).
+		For(&batchv1.CronJob{})
+
+	for _, src := range sources {
+		bldr = bldr.WatchesRawSource(src, handler.EnqueueRequestsFromMapFunc(r.mapChangeToCronJobs))
+	}
+
+	return bldr.Complete(r)
+}
+
+// mapChangeToCronJobs re-reconciles every CronJob in the cluster in response
+// to a netlink/ebpf change, since a single interface or flow event doesn't
+// identify which CronJob (if any) cares about it.
+func (r *CronJobReconciler) mapChangeToCronJobs(ctx context.Context, _ client.Object) []ctrl.Request {
+	var cronJobs batchv1.CronJobList
+	if err := r.List(ctx, &cronJobs); err != nil {
+		return nil
+	}
+
+	requests := make([]ctrl.Request, 0, len(cronJobs.Items))
+	for i := range cronJobs.Items {
+		requests = append(requests, ctrl.Request{
+			NamespacedName: client.ObjectKeyFromObject(&cronJobs.Items[i]),
+		})
+	}
+	return requests
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"sync"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// WaitForCRDsReconciler watches CustomResourceDefinition objects and becomes
+// Ready once every CRD named in RequiredCRDs has the Established condition.
+// main.go uses it to defer registering the downstream reconcilers (e.g.
+// CronJobReconciler) until their CRDs actually exist, instead of letting the
+// manager crash-loop against a cluster where they haven't been installed yet.
+type WaitForCRDsReconciler struct {
+	client.Client
+
+	// RequiredCRDs are the full CRD names (e.g.
+	// "cronjobs.batch.tutorial.kubebuilder.io") that must be Established
+	// before Ready reports true.
+	RequiredCRDs []string
+
+	// OnReady, if set, is invoked the first time every required CRD is
+	// Established. Ready only flips to true once OnReady returns nil, so a
+	// failed attempt is retried on the next Reconcile instead of being
+	// silently stuck forever.
+	OnReady func(ctx context.Context) error
+
+	mu    sync.Mutex
+	ready bool
+}
+
+// Ready reports whether every required CRD has been observed as Established.
+func (r *WaitForCRDsReconciler) Ready() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ready
+}
+
+// SetupWithManager registers the reconciler to watch CustomResourceDefinitions.
+func (r *WaitForCRDsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&apiextensionsv1.CustomResourceDefinition{}).
+		Complete(r)
+}
+
+// Reconcile re-checks every required CRD and, the first time all of them are
+// Established, fires OnReady and only then flips Ready. If OnReady returns an
+// error, ready stays false so the next Reconcile (triggered by the CRD's
+// periodic resync, if nothing else) retries it instead of leaving the
+// downstream controllers unregistered forever.
+func (r *WaitForCRDsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if r.Ready() {
+		return ctrl.Result{}, nil
+	}
+
+	for _, name := range r.RequiredCRDs {
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := r.Get(ctx, client.ObjectKey{Name: name}, crd); err != nil {
+			if apierrors.IsNotFound(err) {
+				return ctrl.Result{}, nil
+			}
+			return ctrl.Result{}, err
+		}
+		if !crdEstablished(crd) {
+			return ctrl.Result{}, nil
+		}
+	}
+
+	if r.OnReady != nil {
+		if err := r.OnReady(ctx); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	r.mu.Lock()
+	r.ready = true
+	r.mu.Unlock()
+
+	return ctrl.Result{}, nil
+}
+
+func crdEstablished(crd *apiextensionsv1.CustomResourceDefinition) bool {
+	for _, cond := range crd.Status.Conditions {
+		if cond.Type == apiextensionsv1.Established && cond.Status == apiextensionsv1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
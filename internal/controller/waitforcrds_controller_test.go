@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestCRD(name string, established bool) *apiextensionsv1.CustomResourceDefinition {
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}
+	if established {
+		crd.Status.Conditions = []apiextensionsv1.CustomResourceDefinitionCondition{
+			{Type: apiextensionsv1.Established, Status: apiextensionsv1.ConditionTrue},
+		}
+	}
+	return crd
+}
+
+func TestWaitForCRDsReconcilerRetriesOnReadyUntilItSucceeds(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := apiextensionsv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add apiextensionsv1 to scheme: %v", err)
+	}
+
+	const crdName = "cronjobs.batch.tutorial.kubebuilder.io"
+	crd := newTestCRD(crdName, false)
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(crd).Build()
+
+	onReadyErr := fmt.Errorf("cronjob controller setup failed")
+	onReadyCalls := 0
+	r := &WaitForCRDsReconciler{
+		Client:       cli,
+		RequiredCRDs: []string{crdName},
+		OnReady: func(ctx context.Context) error {
+			onReadyCalls++
+			return onReadyErr
+		},
+	}
+
+	req := ctrl.Request{}
+
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("reconcile with CRD not yet Established: unexpected error %v", err)
+	}
+	if r.Ready() {
+		t.Fatal("reconciler reports ready before the CRD is Established")
+	}
+	if onReadyCalls != 0 {
+		t.Fatalf("OnReady called before the CRD is Established: %d calls", onReadyCalls)
+	}
+
+	crd.Status.Conditions = newTestCRD(crdName, true).Status.Conditions
+	if err := cli.Status().Update(context.Background(), crd); err != nil {
+		t.Fatalf("mark CRD Established: %v", err)
+	}
+
+	if _, err := r.Reconcile(context.Background(), req); err != onReadyErr {
+		t.Fatalf("reconcile with failing OnReady: got err %v, want %v", err, onReadyErr)
+	}
+	if r.Ready() {
+		t.Fatal("reconciler reports ready even though OnReady failed")
+	}
+	if onReadyCalls != 1 {
+		t.Fatalf("expected OnReady to be called once, got %d", onReadyCalls)
+	}
+
+	r.OnReady = func(ctx context.Context) error {
+		onReadyCalls++
+		return nil
+	}
+
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("reconcile with succeeding OnReady: unexpected error %v", err)
+	}
+	if !r.Ready() {
+		t.Fatal("reconciler should report ready once OnReady succeeds")
+	}
+	if onReadyCalls != 2 {
+		t.Fatalf("expected OnReady to have been called twice (failed, then succeeded), got %d", onReadyCalls)
+	}
+
+	if _, err := r.Reconcile(context.Background(), req); err != nil {
+		t.Fatalf("reconcile once already ready: unexpected error %v", err)
+	}
+	if onReadyCalls != 2 {
+		t.Fatalf("OnReady should not be called again once ready, got %d calls", onReadyCalls)
+	}
+}
@@ -0,0 +1,124 @@
+package monitoring
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// debounceKey identifies the (kind, interface) bucket a raw update is
+// coalesced into. Namespace/Name are included because NetnsWatcher tags
+// per-pod events with the owning pod's identity, and pod default interfaces
+// are almost universally named eth0 - without them, two different pods'
+// eth0 changes would collide on the same bucket and one would clobber the
+// other.
+type debounceKey struct {
+	kind      ChangeKind
+	ifName    string
+	namespace string
+	name      string
+}
+
+// Debouncer buffers NetlinkChange events for Window and emits at most one
+// coalesced event per (Kind, IfName, Namespace, Name) at the end of the
+// window, so that interface flaps, bulk route table changes or bond/bridge
+// enslavement bursts collapse into a single reconcile instead of one per raw
+// update. It
+// also keeps a ring buffer of the most recent raw updates (regardless of
+// whether they were coalesced) so a reconciler can inspect what happened
+// during the window.
+//
+// A Debouncer is used by setting NetlinkSource.Debounce before Start; it is
+// not meant to be used directly.
+type Debouncer struct {
+	// Window is how long the Debouncer collects updates, counted from the
+	// first update after the previous flush, before it emits one coalesced
+	// event for every bucket pending at that moment.
+	Window time.Duration
+	// RingSize is the number of most recent raw updates kept for
+	// inspection. Zero disables the ring buffer.
+	RingSize int
+
+	next EventSink
+
+	mu      sync.Mutex
+	pending map[debounceKey]*NetlinkChange
+	timer   *time.Timer
+	ring    []NetlinkChange
+	ringPos int
+}
+
+// NewDebouncer returns a Debouncer with the given window and ring buffer
+// size. Its next sink is wired up by NetlinkSource.Start.
+func NewDebouncer(window time.Duration, ringSize int) *Debouncer {
+	return &Debouncer{
+		Window:   window,
+		RingSize: ringSize,
+		pending:  make(map[debounceKey]*NetlinkChange),
+	}
+}
+
+// Dispatch implements EventSink. It records change in the ring buffer and
+// either starts a new debounce window for its (Kind, IfName, Namespace, Name)
+// bucket or folds it into the event already pending for that bucket.
+func (d *Debouncer) Dispatch(ctx context.Context, change *NetlinkChange) {
+	if change == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.record(*change)
+
+	key := debounceKey{change.Kind, change.IfName, change.GetNamespace(), change.GetName()}
+	if _, pending := d.pending[key]; pending {
+		debounceCoalesced.WithLabelValues(string(change.Kind)).Inc()
+	}
+	d.pending[key] = change
+
+	if d.timer == nil {
+		d.timer = time.AfterFunc(d.Window, func() { d.flush(ctx) })
+	}
+}
+
+// record appends change to the ring buffer, overwriting the oldest entry
+// once RingSize is reached.
+func (d *Debouncer) record(change NetlinkChange) {
+	if d.RingSize <= 0 {
+		return
+	}
+	if len(d.ring) < d.RingSize {
+		d.ring = append(d.ring, change)
+		return
+	}
+	d.ring[d.ringPos] = change
+	d.ringPos = (d.ringPos + 1) % d.RingSize
+}
+
+// Recent returns the raw updates currently held in the ring buffer, oldest
+// first.
+func (d *Debouncer) Recent() []NetlinkChange {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	out := make([]NetlinkChange, len(d.ring))
+	for i := range d.ring {
+		out[i] = d.ring[(d.ringPos+i)%len(d.ring)]
+	}
+	return out
+}
+
+// flush emits one event per bucket pending at the end of the window.
+func (d *Debouncer) flush(ctx context.Context) {
+	d.mu.Lock()
+	pending := d.pending
+	d.pending = make(map[debounceKey]*NetlinkChange)
+	d.timer = nil
+	d.mu.Unlock()
+
+	for _, change := range pending {
+		debounceEmitted.WithLabelValues(string(change.Kind)).Inc()
+		d.next.Dispatch(ctx, change)
+	}
+}
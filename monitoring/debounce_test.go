@@ -0,0 +1,128 @@
+package monitoring
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// collectingSink records every change it's asked to Dispatch.
+type collectingSink struct {
+	changes chan *NetlinkChange
+}
+
+func newCollectingSink() *collectingSink {
+	return &collectingSink{changes: make(chan *NetlinkChange, 16)}
+}
+
+func (s *collectingSink) Dispatch(_ context.Context, change *NetlinkChange) {
+	s.changes <- change
+}
+
+func TestDebouncerCoalescesBurstsIntoOneEmittedEvent(t *testing.T) {
+	sink := newCollectingSink()
+	d := NewDebouncer(20*time.Millisecond, 0)
+	d.next = sink
+
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		d.Dispatch(ctx, &NetlinkChange{Kind: ChangeKindLink, IfName: "eth0", Op: OpUpdate})
+	}
+
+	select {
+	case change := <-sink.changes:
+		if change.IfName != "eth0" {
+			t.Fatalf("emitted change for wrong interface: %+v", change)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for debounced event")
+	}
+
+	select {
+	case change := <-sink.changes:
+		t.Fatalf("expected only one emitted event for the coalesced burst, got a second: %+v", change)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestDebouncerEmitsSeparateEventsPerBucket(t *testing.T) {
+	sink := newCollectingSink()
+	d := NewDebouncer(20*time.Millisecond, 0)
+	d.next = sink
+
+	ctx := context.Background()
+	d.Dispatch(ctx, &NetlinkChange{Kind: ChangeKindLink, IfName: "eth0", Op: OpUpdate})
+	d.Dispatch(ctx, &NetlinkChange{Kind: ChangeKindLink, IfName: "eth1", Op: OpUpdate})
+
+	seen := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		select {
+		case change := <-sink.changes:
+			seen[change.IfName] = true
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for event %d", i)
+		}
+	}
+	if !seen["eth0"] || !seen["eth1"] {
+		t.Fatalf("expected one emitted event per bucket, got %v", seen)
+	}
+}
+
+func TestDebouncerKeysBucketsByNamespaceAndName(t *testing.T) {
+	sink := newCollectingSink()
+	d := NewDebouncer(20*time.Millisecond, 0)
+	d.next = sink
+
+	ctx := context.Background()
+	podA := &NetlinkChange{Kind: ChangeKindLink, IfName: "eth0", Op: OpUpdate}
+	podA.SetNamespace("default")
+	podA.SetName("pod-a")
+	podB := &NetlinkChange{Kind: ChangeKindLink, IfName: "eth0", Op: OpUpdate}
+	podB.SetNamespace("default")
+	podB.SetName("pod-b")
+
+	d.Dispatch(ctx, podA)
+	d.Dispatch(ctx, podB)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 2; i++ {
+		select {
+		case change := <-sink.changes:
+			seen[change.GetName()] = true
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for event %d", i)
+		}
+	}
+	if !seen["pod-a"] || !seen["pod-b"] {
+		t.Fatalf("two pods reporting the same IfName clobbered each other's bucket, got %v", seen)
+	}
+}
+
+func TestDebouncerRecentWrapsAroundRingBuffer(t *testing.T) {
+	d := NewDebouncer(time.Hour, 3)
+	// next is never driven since Window is long; Dispatch only needs to
+	// record into the ring here.
+	d.next = EventSinkFunc(func(context.Context, *NetlinkChange) {})
+
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		d.Dispatch(ctx, &NetlinkChange{Kind: ChangeKindLink, IfName: indexToIfName(i)})
+	}
+
+	recent := d.Recent()
+	if len(recent) != 3 {
+		t.Fatalf("expected ring buffer capped at RingSize=3, got %d entries", len(recent))
+	}
+	// Only the last 3 of the 5 dispatched changes (ifaces 2, 3, 4) should
+	// remain, oldest first.
+	want := []string{"if2", "if3", "if4"}
+	for i, w := range want {
+		if recent[i].IfName != w {
+			t.Fatalf("Recent()[%d] = %q, want %q (full: %+v)", i, recent[i].IfName, w, recent)
+		}
+	}
+}
+
+func indexToIfName(i int) string {
+	return "if" + string(rune('0'+i))
+}
@@ -0,0 +1,37 @@
+package monitoring
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	eventsProcessed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "netlink_source_events_processed_total",
+		Help: "Number of typed netlink change events delivered to the controller-runtime source.",
+	}, []string{"kind"})
+
+	eventsFiltered = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "netlink_source_events_filtered_total",
+		Help: "Number of netlink change events rejected by a predicate.Predicate passed to Start. This is expected filtering, not backpressure.",
+	}, []string{"kind"})
+
+	subscriptionRestarts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "netlink_source_subscription_restarts_total",
+		Help: "Number of times a netlink subscription was restarted after a socket error.",
+	}, []string{"kind"})
+
+	debounceCoalesced = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "netlink_source_debounce_coalesced_total",
+		Help: "Number of raw updates folded into an already-pending debounced event instead of being emitted on their own.",
+	}, []string{"kind"})
+
+	debounceEmitted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "netlink_source_debounce_emitted_total",
+		Help: "Number of coalesced events emitted by the Debouncer after its window elapsed.",
+	}, []string{"kind"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(eventsProcessed, eventsFiltered, subscriptionRestarts, debounceCoalesced, debounceEmitted)
+}
@@ -0,0 +1,199 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NetnsWatcher reconciles Pods scheduled on this node and keeps a netlink
+// subscription open inside each pod's network namespace, so that secondary
+// interfaces created by CNI plugins other than the default one are observed
+// too, not just interfaces in the host netns. Discovered changes are handed
+// to sink using the same NetlinkChange type the host-netns NetlinkSource
+// produces, tagged with the owning pod's namespace and name.
+type NetnsWatcher struct {
+	client.Client
+
+	// NodeName restricts reconciliation to pods scheduled on this node.
+	NodeName string
+
+	sink EventSink
+
+	mu      sync.Mutex
+	cancels map[types.NamespacedName]context.CancelFunc
+}
+
+// NewNetnsWatcher returns a NetnsWatcher that delivers events to sink.
+func NewNetnsWatcher(cli client.Client, nodeName string, sink EventSink) *NetnsWatcher {
+	return &NetnsWatcher{
+		Client:   cli,
+		NodeName: nodeName,
+		sink:     sink,
+		cancels:  make(map[types.NamespacedName]context.CancelFunc),
+	}
+}
+
+// SetupWithManager registers the watcher as a Pod controller.
+func (w *NetnsWatcher) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Pod{}).
+		Complete(w)
+}
+
+// Reconcile starts a netns subscription for newly observed pods on this node
+// and tears it down once the pod is deleted or leaves the node.
+func (w *NetnsWatcher) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	pod := &corev1.Pod{}
+	if err := w.Get(ctx, req.NamespacedName, pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			w.stop(req.NamespacedName)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if pod.Spec.NodeName != w.NodeName || pod.Status.Phase != corev1.PodRunning {
+		w.stop(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	pid, err := containerPID(pod)
+	if err != nil {
+		// Container runtime hasn't reported a PID yet; retry once it does.
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	w.start(ctx, req.NamespacedName, pid)
+	return ctrl.Result{}, nil
+}
+
+// start opens a netlink subscription in the netns of pid, unless one has
+// already been started for name and not stopped since. A subscription whose
+// goroutine has exited with an error is not restarted until stop is called
+// for name.
+func (w *NetnsWatcher) start(ctx context.Context, name types.NamespacedName, pid int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.cancels[name]; ok {
+		return
+	}
+	nsCtx, cancel := context.WithCancel(ctx)
+	w.cancels[name] = cancel
+
+	go func() {
+		if err := w.serveNetns(nsCtx, name, pid); err != nil && nsCtx.Err() == nil {
+			klog.Errorf("netns watcher: %s: %v", name, err)
+		}
+	}()
+}
+
+// stop cancels the subscription running for name, if any.
+func (w *NetnsWatcher) stop(name types.NamespacedName) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if cancel, ok := w.cancels[name]; ok {
+		cancel()
+		delete(w.cancels, name)
+	}
+}
+
+// serveNetns subscribes to link, address and route updates inside the
+// network namespace of pid and dispatches them tagged with name, until ctx
+// is cancelled.
+func (w *NetnsWatcher) serveNetns(ctx context.Context, name types.NamespacedName, pid int) error {
+	ns, err := netns.GetFromPid(pid)
+	if err != nil {
+		return fmt.Errorf("open netns for pid %d: %w", pid, err)
+	}
+	defer ns.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	chLink := make(chan netlink.LinkUpdate)
+	if err := netlink.LinkSubscribeAt(ns, chLink, done); err != nil {
+		return fmt.Errorf("subscribe links at pid %d: %w", pid, err)
+	}
+	chAddr := make(chan netlink.AddrUpdate)
+	if err := netlink.AddrSubscribeAt(ns, chAddr, done); err != nil {
+		return fmt.Errorf("subscribe addrs at pid %d: %w", pid, err)
+	}
+	chRoute := make(chan netlink.RouteUpdate)
+	if err := netlink.RouteSubscribeAt(ns, chRoute, done); err != nil {
+		return fmt.Errorf("subscribe routes at pid %d: %w", pid, err)
+	}
+
+	interfaces := make(map[string]bool)
+	newlyCreated := make(map[string]bool)
+
+	for {
+		var change *NetlinkChange
+		select {
+		case <-ctx.Done():
+			return nil
+		case u := <-chLink:
+			change = linkChange(u, interfaces, newlyCreated)
+		case u := <-chAddr:
+			var ok bool
+			change, ok = addrChange(u)
+			if !ok {
+				continue
+			}
+		case u := <-chRoute:
+			change = routeChange(u)
+		}
+		change.SetNamespace(name.Namespace)
+		change.SetName(name.Name)
+		w.sink.Dispatch(ctx, change)
+	}
+}
+
+// containerPID returns the PID of the first running container of pod, found
+// by walking the kubelet cgroup hierarchy for the pod's UID. Pods whose
+// runtime hasn't reported a container yet return an error.
+func containerPID(pod *corev1.Pod) (int, error) {
+	podDir := "pod" + strings.ReplaceAll(string(pod.UID), "-", "_")
+
+	var pid int
+	err := filepath.WalkDir("/sys/fs/cgroup", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || pid != 0 {
+			return nil
+		}
+		if !d.IsDir() || !strings.Contains(path, podDir) {
+			return nil
+		}
+		procs, err := os.ReadFile(filepath.Join(path, "cgroup.procs"))
+		if err != nil {
+			return nil
+		}
+		for _, line := range strings.Fields(string(procs)) {
+			if v, err := strconv.Atoi(line); err == nil {
+				pid = v
+				return filepath.SkipAll
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if pid == 0 {
+		return 0, fmt.Errorf("no container PID found for pod %s/%s yet", pod.Namespace, pod.Name)
+	}
+	return pid, nil
+}
@@ -0,0 +1,292 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// Bits of the Watch bitmask accepted by NewNetlinkSource, replacing the old
+// 1-7 StartMonitoring flag.
+const (
+	WatchLink  = 1 << 0
+	WatchAddr  = 1 << 1
+	WatchRoute = 1 << 2
+)
+
+// restartBackoff is how long NetlinkSource waits before re-subscribing after
+// a netlink socket error.
+const restartBackoff = 2 * time.Second
+
+// EventSink is implemented by anything that can filter and deliver a
+// NetlinkChange to the controller-runtime handler registered with Start.
+// NetlinkSource implements it for the host netns subscriptions; NetnsWatcher
+// uses it to fan per-pod events into the same stream. The ebpf event source
+// does not go through it: it delivers to its own handler directly.
+type EventSink interface {
+	Dispatch(ctx context.Context, change *NetlinkChange)
+}
+
+// EventSinkFunc adapts a plain function to an EventSink.
+type EventSinkFunc func(ctx context.Context, change *NetlinkChange)
+
+// Dispatch implements EventSink.
+func (f EventSinkFunc) Dispatch(ctx context.Context, change *NetlinkChange) { f(ctx, change) }
+
+// NetlinkSource is a controller-runtime source.Source that watches link,
+// address and route changes on the host network namespace and delivers them
+// as typed NetlinkChange events, so reconcilers can filter on interface name,
+// address family or event kind instead of reacting to an opaque tick.
+//
+// It is meant to be registered with builder.WatchesRawSource, which drives
+// its Start method with the controller's own context, so shutdown and
+// restart-on-error are handled without a leaked bare goroutine.
+type NetlinkSource struct {
+	// Watch is a bitmask of WatchLink, WatchAddr and WatchRoute selecting
+	// which subscriptions to open.
+	Watch int
+
+	// Debounce, if set, coalesces bursts of raw updates before they reach
+	// the controller-runtime handler. It must be set before Start is
+	// called.
+	Debounce *Debouncer
+
+	handler    handler.EventHandler
+	queue      workqueue.RateLimitingInterface
+	predicates []predicate.Predicate
+
+	// ready is closed once Start has assigned handler, queue and
+	// predicates. Dispatch waits on it so a caller that already holds a
+	// reference to this source as an EventSink - NetnsWatcher, in
+	// particular, which runs as its own controller and can start
+	// reconciling pods before the manager has driven this source's Start -
+	// can't land on a nil handler.
+	ready chan struct{}
+}
+
+// NewNetlinkSource returns a NetlinkSource watching the kinds of change set
+// in watch (an OR of WatchLink, WatchAddr, WatchRoute).
+func NewNetlinkSource(watch int) *NetlinkSource {
+	return &NetlinkSource{Watch: watch, ready: make(chan struct{})}
+}
+
+// Start implements source.Source. It stores the handler, queue and
+// predicates supplied by the controller and runs the subscription loop until
+// ctx is cancelled.
+func (s *NetlinkSource) Start(ctx context.Context, h handler.EventHandler, q workqueue.RateLimitingInterface, predicates ...predicate.Predicate) error {
+	s.handler = h
+	s.queue = q
+	s.predicates = predicates
+	if s.Debounce != nil {
+		s.Debounce.next = EventSinkFunc(s.dispatchToHandler)
+	}
+	close(s.ready)
+
+	go s.run(ctx)
+	return nil
+}
+
+// run keeps the netlink subscriptions alive for the lifetime of ctx,
+// restarting them with a backoff whenever the underlying socket errors out.
+func (s *NetlinkSource) run(ctx context.Context) {
+	for {
+		err := s.subscribeAndServe(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			klog.Errorf("netlink source: %v, restarting subscriptions", err)
+			subscriptionRestarts.WithLabelValues("all").Inc()
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(restartBackoff):
+			}
+			continue
+		}
+		return
+	}
+}
+
+// subscribeAndServe opens link, address and route subscriptions (as selected
+// by s.Watch) and fans their updates into dispatch until ctx is cancelled or
+// one of the subscriptions reports an error.
+func (s *NetlinkSource) subscribeAndServe(ctx context.Context) error {
+	done := make(chan struct{})
+	defer close(done)
+
+	errCh := make(chan error, 3)
+	onError := func(err error) { errCh <- err }
+
+	var chLink chan netlink.LinkUpdate
+	var chAddr chan netlink.AddrUpdate
+	var chRoute chan netlink.RouteUpdate
+
+	if s.Watch&WatchLink != 0 {
+		chLink = make(chan netlink.LinkUpdate)
+		if err := netlink.LinkSubscribeWithOptions(chLink, done, netlink.LinkSubscribeOptions{ErrorCallback: onError}); err != nil {
+			return err
+		}
+	}
+	if s.Watch&WatchAddr != 0 {
+		chAddr = make(chan netlink.AddrUpdate)
+		if err := netlink.AddrSubscribeWithOptions(chAddr, done, netlink.AddrSubscribeOptions{ErrorCallback: onError}); err != nil {
+			return err
+		}
+	}
+	if s.Watch&WatchRoute != 0 {
+		chRoute = make(chan netlink.RouteUpdate)
+		if err := netlink.RouteSubscribeWithOptions(chRoute, done, netlink.RouteSubscribeOptions{ErrorCallback: onError}); err != nil {
+			return err
+		}
+	}
+
+	newlyCreated := make(map[string]bool)
+	interfaces := make(map[string]bool)
+	links, err := netlink.LinkList()
+	if err != nil {
+		return err
+	}
+	for _, link := range links {
+		interfaces[link.Attrs().Name] = true
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errCh:
+			return err
+		case updateLink, ok := <-chLink:
+			if !ok {
+				return fmt.Errorf("link subscription closed")
+			}
+			s.Dispatch(ctx, linkChange(updateLink, interfaces, newlyCreated))
+		case updateAddr, ok := <-chAddr:
+			if !ok {
+				return fmt.Errorf("address subscription closed")
+			}
+			if change, ok := addrChange(updateAddr); ok {
+				s.Dispatch(ctx, change)
+			}
+		case updateRoute, ok := <-chRoute:
+			if !ok {
+				return fmt.Errorf("route subscription closed")
+			}
+			s.Dispatch(ctx, routeChange(updateRoute))
+		}
+	}
+}
+
+// Dispatch routes change through Debounce, if configured, and otherwise
+// straight to the handler registered in Start. It implements EventSink and is
+// the single entry point used both by this source's own subscription loop
+// and by NetnsWatcher, so per-pod events get the same coalescing and
+// predicate filtering as host-netns ones.
+//
+// It blocks until Start has run (or ctx is done), since a caller holding this
+// source as an EventSink - again, NetnsWatcher - may start dispatching before
+// the manager drives Start.
+func (s *NetlinkSource) Dispatch(ctx context.Context, change *NetlinkChange) {
+	if change == nil {
+		return
+	}
+	select {
+	case <-s.ready:
+	case <-ctx.Done():
+		return
+	}
+
+	if s.Debounce != nil {
+		s.Debounce.Dispatch(ctx, change)
+		return
+	}
+	s.dispatchToHandler(ctx, change)
+}
+
+// dispatchToHandler runs predicates against change and, if they all pass,
+// delivers it to the handler registered in Start. It assumes Start has
+// already run - Dispatch guarantees that before calling it, whether directly
+// or via Debounce.next.
+func (s *NetlinkSource) dispatchToHandler(ctx context.Context, change *NetlinkChange) {
+	evt := event.GenericEvent{Object: change}
+	for _, p := range s.predicates {
+		if !p.Generic(evt) {
+			eventsFiltered.WithLabelValues(string(change.Kind)).Inc()
+			return
+		}
+	}
+	eventsProcessed.WithLabelValues(string(change.Kind)).Inc()
+	s.handler.Generic(ctx, evt, s.queue)
+}
+
+func linkChange(updateLink netlink.LinkUpdate, interfaces, newlyCreated map[string]bool) *NetlinkChange {
+	name := updateLink.Link.Attrs().Name
+	change := &NetlinkChange{Kind: ChangeKindLink, IfName: name, Index: updateLink.Link.Attrs().Index}
+
+	switch {
+	case updateLink.Header.Type == syscall.RTM_DELLINK:
+		delete(interfaces, name)
+		delete(newlyCreated, name)
+		change.Op = OpDelete
+	case !interfaces[name] && updateLink.Header.Type == syscall.RTM_NEWLINK:
+		interfaces[name] = true
+		newlyCreated[name] = true
+		change.Op = OpAdd
+	default:
+		if updateLink.Link.Attrs().Flags&net.FlagUp != 0 {
+			delete(newlyCreated, name)
+		}
+		change.Op = OpUpdate
+	}
+	return change
+}
+
+func addrChange(updateAddr netlink.AddrUpdate) (*NetlinkChange, bool) {
+	iface, err := net.InterfaceByIndex(updateAddr.LinkIndex)
+	if err != nil {
+		// The interface is already gone; report the change without a name.
+		return &NetlinkChange{
+			Kind:  ChangeKindAddr,
+			Index: updateAddr.LinkIndex,
+			IP:    updateAddr.LinkAddress.IP.String(),
+			Op:    OpDelete,
+		}, true
+	}
+	op := OpDelete
+	if updateAddr.NewAddr {
+		op = OpAdd
+	}
+	return &NetlinkChange{
+		Kind:   ChangeKindAddr,
+		IfName: iface.Name,
+		Index:  updateAddr.LinkIndex,
+		IP:     updateAddr.LinkAddress.IP.String(),
+		Op:     op,
+	}, true
+}
+
+func routeChange(updateRoute netlink.RouteUpdate) *NetlinkChange {
+	op := OpUpdate
+	switch updateRoute.Type {
+	case syscall.RTM_NEWROUTE:
+		op = OpAdd
+	case syscall.RTM_DELROUTE:
+		op = OpDelete
+	}
+	return &NetlinkChange{
+		Kind:  ChangeKindRoute,
+		Index: updateRoute.Route.LinkIndex,
+		Route: updateRoute.Route.Dst.String(),
+		Op:    op,
+	}
+}
@@ -0,0 +1,87 @@
+package monitoring
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ChangeKind identifies which kind of netlink object a NetlinkChange describes.
+type ChangeKind string
+
+const (
+	ChangeKindLink  ChangeKind = "Link"
+	ChangeKindAddr  ChangeKind = "Addr"
+	ChangeKindRoute ChangeKind = "Route"
+	// ChangeKindFlow identifies events produced by the ebpf event source,
+	// which observes socket/connection lifecycle rather than link/addr/route
+	// changes.
+	ChangeKindFlow ChangeKind = "Flow"
+)
+
+// Op identifies the operation that produced a NetlinkChange.
+type Op string
+
+const (
+	OpAdd    Op = "Add"
+	OpUpdate Op = "Update"
+	OpDelete Op = "Delete"
+)
+
+// NetlinkChange is the typed event that NetlinkSource, NetnsWatcher and the
+// ebpf event source deliver to controller-runtime handlers. It implements runtime.Object
+// (via a hand-written DeepCopyObject, since there is no CRD/codegen backing it)
+// so it can flow through event.GenericEvent and be filtered with predicate.Funcs.
+type NetlinkChange struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Kind ChangeKind
+	Op   Op
+
+	// IfName is the name of the interface the change applies to. It is empty
+	// for Route and Flow changes, and for Addr changes whose interface could
+	// not be looked up.
+	IfName string
+	// Index is the kernel interface index, set for Link, Addr and Route
+	// changes, and for Flow changes from the net_dev_queue tracepoint.
+	Index int
+	// IP is set for Addr changes.
+	IP string
+	// Route is a human-readable description of the route, set for Route changes.
+	Route string
+
+	// SrcIP, DstIP, SrcPort and DstPort describe the connection a Flow change
+	// was observed on.
+	SrcIP   string
+	DstIP   string
+	SrcPort uint16
+	DstPort uint16
+	// PID is the process that owned the socket a Flow change was observed on.
+	PID int
+}
+
+// GetObjectKind implements runtime.Object.
+func (in *NetlinkChange) GetObjectKind() schema.ObjectKind { return &in.TypeMeta }
+
+// DeepCopyObject implements runtime.Object.
+func (in *NetlinkChange) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := new(NetlinkChange)
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Kind = in.Kind
+	out.Op = in.Op
+	out.IfName = in.IfName
+	out.Index = in.Index
+	out.IP = in.IP
+	out.Route = in.Route
+	out.SrcIP = in.SrcIP
+	out.DstIP = in.DstIP
+	out.SrcPort = in.SrcPort
+	out.DstPort = in.DstPort
+	out.PID = in.PID
+	return out
+}